@@ -0,0 +1,151 @@
+// Package attester implements the attester actor, which signs attestations
+// for the collations the beacon client reports on its configured shards.
+// Collations are not validated and attestations are not gossiped over p2p.
+package attester
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/client/beacon"
+	"github.com/prysmaticlabs/prysm/shared/accounts"
+	"github.com/prysmaticlabs/prysm/shared/event"
+	"github.com/prysmaticlabs/prysm/shared/rpc"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logrus.WithField("prefix", "attester")
+
+// attestInterval is how often the main loop checks for a collation to
+// attest on each configured shard, standing in for a real slot clock
+// until one exists.
+const attestInterval = 6 * time.Second
+
+// AttestedEvent is posted to the node's shared event feed every time this
+// actor successfully signs an attestation, so other services (e.g.
+// shardstats) can observe the latest attested period per shard without
+// polling this actor directly.
+type AttestedEvent struct {
+	ShardID uint64
+	Period  uint64
+}
+
+// Attester is the actor service responsible for attesting to shard
+// collations. It runs independently of any proposer actor also registered
+// on the node, so a single node can attest and propose concurrently.
+type Attester struct {
+	beacon  *beacon.Service
+	account *accounts.Manager
+	address string
+	shards  []uint64
+	wg      *sync.WaitGroup
+	mux     *event.TypeMux
+
+	mu      sync.Mutex
+	running bool
+}
+
+// NewAttester creates a new attester actor backed by beaconService,
+// signing attestations with address out of account. address must already
+// be unlocked before Start is called. shards lists the shard IDs this
+// attester attests on. wg is incremented for the lifetime of the
+// attestation loop goroutine Start spawns. mux receives an AttestedEvent
+// for every attestation signed.
+func NewAttester(beaconService *beacon.Service, account *accounts.Manager, address string, shards []uint64, wg *sync.WaitGroup, mux *event.TypeMux) *Attester {
+	return &Attester{beacon: beaconService, account: account, address: address, shards: shards, wg: wg, mux: mux}
+}
+
+// Start kicks off the attester's main loop. The loop goroutine watches ctx
+// and exits as soon as it is cancelled.
+func (a *Attester) Start(ctx context.Context) error {
+	log.Infof("Starting attester service for shards %v", a.shards)
+	a.setRunning(true)
+	a.wg.Add(1)
+	go a.loop(ctx)
+	return nil
+}
+
+// Stop is a no-op; the attestation loop exits via ctx cancellation and
+// joins on the shared WaitGroup.
+func (a *Attester) Stop() error {
+	return nil
+}
+
+func (a *Attester) loop(ctx context.Context) {
+	defer a.wg.Done()
+	defer a.setRunning(false)
+
+	ticker := time.NewTicker(attestInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.attestShards()
+		}
+	}
+}
+
+// attestShards attempts to sign an attestation for the latest collation
+// observed on every configured shard. It is a no-op for a shard until the
+// beacon client has a collation to attest to, and a no-op entirely if no
+// account was unlocked for this actor.
+func (a *Attester) attestShards() {
+	if a.address == "" {
+		return
+	}
+	const period = 0 // TODO: derive from the beacon-reported slot once streaming exists.
+	for _, shard := range a.shards {
+		collation, err := a.beacon.GetCollation(shard, period)
+		if err != nil {
+			continue
+		}
+		if _, err := a.signAttestation(collationDigest(shard, collation)); err != nil {
+			log.Warnf("shard %d: could not sign attestation: %v", shard, err)
+			continue
+		}
+		a.mux.Post(AttestedEvent{ShardID: shard, Period: period})
+	}
+}
+
+func (a *Attester) setRunning(running bool) {
+	a.mu.Lock()
+	a.running = running
+	a.mu.Unlock()
+}
+
+// APIs returns the RPC namespaces this service exposes over the node's
+// HTTP/IPC endpoints.
+func (a *Attester) APIs() []rpc.API {
+	return []rpc.API{
+		{Namespace: "attester", Version: "1.0", Service: a},
+	}
+}
+
+// Status reports whether the attester's main loop is currently running.
+func (a *Attester) Status() (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.running, nil
+}
+
+// signAttestation signs a collation's attestation digest with this
+// attester's unlocked account. attestShards discards the signature; it is
+// not gossiped over p2p.
+func (a *Attester) signAttestation(collationHash [32]byte) ([]byte, error) {
+	digest := sha256.Sum256(collationHash[:])
+	return a.account.Sign(a.address, digest[:])
+}
+
+// collationDigest derives the digest identifying shard's collation that
+// signAttestation signs over.
+func collationDigest(shard uint64, collation string) [32]byte {
+	buf := make([]byte, 8, 8+len(collation))
+	binary.BigEndian.PutUint64(buf, shard)
+	buf = append(buf, collation...)
+	return sha256.Sum256(buf)
+}
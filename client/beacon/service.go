@@ -0,0 +1,69 @@
+// Package beacon streams state from a beacon node over RPC so that
+// attester and proposer actors can act on the latest shard assignments.
+package beacon
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/prysmaticlabs/prysm/client/rpcclient"
+	"github.com/prysmaticlabs/prysm/shared/rpc"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logrus.WithField("prefix", "beacon")
+
+// Config configures the beacon client service.
+type Config struct{}
+
+// DefaultConfig returns sane defaults for the beacon client service.
+func DefaultConfig() *Config {
+	return &Config{}
+}
+
+// Service is the shard node's client of a beacon node. It receives no
+// beacon chain state, so GetCollation reports no collation for any shard.
+type Service struct {
+	config *Config
+	rpc    *rpcclient.Service
+	wg     *sync.WaitGroup
+}
+
+// NewBeaconClient creates a new beacon client service backed by
+// rpcService. wg is incremented for the lifetime of the goroutine Start
+// spawns.
+func NewBeaconClient(config *Config, rpcService *rpcclient.Service, wg *sync.WaitGroup) *Service {
+	return &Service{config: config, rpc: rpcService, wg: wg}
+}
+
+// Start spawns a goroutine that exits as soon as ctx is cancelled. It opens
+// no stream to the beacon node.
+func (s *Service) Start(ctx context.Context) error {
+	log.Info("Starting beacon client service")
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		<-ctx.Done()
+	}()
+	return nil
+}
+
+// Stop is a no-op; there is no beacon node stream to terminate.
+func (s *Service) Stop() error {
+	return nil
+}
+
+// APIs returns the RPC namespaces this service exposes over the node's
+// HTTP/IPC endpoints.
+func (s *Service) APIs() []rpc.API {
+	return []rpc.API{
+		{Namespace: "shard", Version: "1.0", Service: s},
+	}
+}
+
+// GetCollation returns the collation header for shardID at the given
+// period, as last observed from the beacon node.
+func (s *Service) GetCollation(shardID uint64, period uint64) (string, error) {
+	return "", fmt.Errorf("no collation observed yet for shard %d, period %d", shardID, period)
+}
@@ -0,0 +1,64 @@
+package node
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/prysmaticlabs/prysm/client/types"
+	"github.com/prysmaticlabs/prysm/shared/accounts"
+	"github.com/urfave/cli"
+)
+
+const keystoreDirName = "keystore"
+
+// buildAccountManager opens the keystore under dataDir and unlocks every
+// address named by --unlock, matched positionally against the
+// passphrases in the --password file, mirroring geth's startEth.
+func buildAccountManager(ctx *cli.Context, dataDir string) (*accounts.Manager, []string, error) {
+	manager, err := accounts.NewManager(filepath.Join(dataDir, keystoreDirName))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	unlock := ctx.GlobalString(types.UnlockFlag.Name)
+	if unlock == "" {
+		return manager, nil, nil
+	}
+	addresses := strings.Split(unlock, ",")
+
+	passwords, err := readPasswordFile(ctx.GlobalString(types.PasswordFileFlag.Name), len(addresses))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var unlocked []string
+	for i, address := range addresses {
+		address = strings.TrimSpace(address)
+		if _, err := manager.Unlock(address, passwords[i]); err != nil {
+			return nil, nil, fmt.Errorf("could not unlock account %s: %v", address, err)
+		}
+		unlocked = append(unlocked, address)
+	}
+	return manager, unlocked, nil
+}
+
+// readPasswordFile reads one passphrase per line and pads with empty
+// passphrases if the file has fewer lines than accounts to unlock.
+func readPasswordFile(path string, count int) ([]string, error) {
+	passwords := make([]string, count)
+	if path == "" {
+		return passwords, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read --password file: %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	for i := 0; i < count && i < len(lines); i++ {
+		passwords[i] = lines[i]
+	}
+	return passwords, nil
+}
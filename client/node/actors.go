@@ -0,0 +1,49 @@
+package node
+
+import (
+	"strings"
+
+	"github.com/prysmaticlabs/prysm/client/types"
+	"github.com/urfave/cli"
+)
+
+// parseActorRoles flattens --actor, which may be repeated and/or comma
+// separated, into a deduplicated list of roles in first-seen order.
+func parseActorRoles(ctx *cli.Context) []string {
+	var roles []string
+	seen := make(map[string]bool)
+	for _, raw := range ctx.GlobalStringSlice(types.ActorFlag.Name) {
+		for _, role := range strings.Split(raw, ",") {
+			role = strings.TrimSpace(role)
+			if role == "" || seen[role] {
+				continue
+			}
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+func hasActor(roles []string, name string) bool {
+	for _, r := range roles {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveSigningAddress picks the address an actor signs with: flagValue
+// (--attester-account/--proposer-account) if set, otherwise the first
+// address unlocked via --unlock. It returns "" if neither names an
+// address, in which case the actor runs without signing.
+func resolveSigningAddress(flagValue string, unlocked []string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if len(unlocked) > 0 {
+		return unlocked[0]
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+package node
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/client/types"
+	"github.com/urfave/cli"
+)
+
+// contextWithActorFlags builds a *cli.Context with --actor set once per
+// value in values, mirroring how urfave/cli parses a repeatable flag.
+func contextWithActorFlags(t *testing.T, values ...string) *cli.Context {
+	t.Helper()
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	var actors cli.StringSlice
+	set.Var(&actors, types.ActorFlag.Name, types.ActorFlag.Usage)
+
+	args := make([]string, 0, len(values)*2)
+	for _, v := range values {
+		args = append(args, "--"+types.ActorFlag.Name, v)
+	}
+	if err := set.Parse(args); err != nil {
+		t.Fatalf("could not parse flags: %v", err)
+	}
+	return cli.NewContext(nil, set, nil)
+}
+
+func TestParseActorRoles(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   []string
+	}{
+		{"empty", nil, nil},
+		{"single", []string{"attester"}, []string{"attester"}},
+		{"comma separated", []string{"attester,proposer"}, []string{"attester", "proposer"}},
+		{"repeated flag", []string{"attester", "proposer"}, []string{"attester", "proposer"}},
+		{"dedup across both forms", []string{"attester,proposer", "attester"}, []string{"attester", "proposer"}},
+		{"whitespace trimmed", []string{" attester , proposer "}, []string{"attester", "proposer"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := contextWithActorFlags(t, tt.values...)
+			got := parseActorRoles(ctx)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseActorRoles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasActor(t *testing.T) {
+	roles := []string{"attester", "proposer"}
+	if !hasActor(roles, "attester") {
+		t.Error("hasActor() = false, want true for a present role")
+	}
+	if hasActor(roles, "missing") {
+		t.Error("hasActor() = true, want false for an absent role")
+	}
+	if hasActor(nil, "attester") {
+		t.Error("hasActor(nil, ...) = true, want false")
+	}
+}
+
+func TestResolveSigningAddress(t *testing.T) {
+	tests := []struct {
+		name      string
+		flagValue string
+		unlocked  []string
+		want      string
+	}{
+		{"flag set wins", "0xabc", []string{"0xdef"}, "0xabc"},
+		{"falls back to first unlocked", "", []string{"0xdef", "0xghi"}, "0xdef"},
+		{"empty when neither set", "", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolveSigningAddress(tt.flagValue, tt.unlocked)
+			if got != tt.want {
+				t.Errorf("resolveSigningAddress(%q, %v) = %q, want %q", tt.flagValue, tt.unlocked, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package node
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/prysmaticlabs/prysm/client/types"
+	"github.com/prysmaticlabs/prysm/shared/cmd"
+	"github.com/urfave/cli"
+)
+
+// AttachCommand dials a running shard node's IPC socket and drops into a
+// minimal interactive client, mirroring `geth attach`.
+var AttachCommand = cli.Command{
+	Action:    runAttach,
+	Name:      "attach",
+	Usage:     "Start an interactive shell attached to a running shard node's IPC endpoint",
+	ArgsUsage: "[datadir]",
+	Flags:     []cli.Flag{cmd.DataDirFlag, types.IPCPathFlag},
+}
+
+func runAttach(ctx *cli.Context) error {
+	dataDir := ctx.GlobalString(cmd.DataDirFlag.Name)
+	if ctx.NArg() > 0 {
+		dataDir = ctx.Args().First()
+	}
+	socket := filepath.Join(dataDir, ctx.GlobalString(types.IPCPathFlag.Name))
+
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return fmt.Errorf("could not attach to %s: %v", socket, err)
+	}
+	defer conn.Close()
+
+	fmt.Printf("Attached to %s. Enter requests as namespace_method([params...]), Ctrl-D to exit.\n", socket)
+	return runREPL(conn, os.Stdin, os.Stdout)
+}
+
+// runREPL reads one request per line, e.g. "shard_getCollation(0, 1)", and
+// prints the JSON-RPC response, until in is closed.
+func runREPL(conn net.Conn, in *os.File, out *os.File) error {
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, "> ")
+		if !scanner.Scan() {
+			return scanner.Err()
+		}
+
+		method, params, err := parseCall(scanner.Text())
+		if err != nil {
+			fmt.Fprintf(out, "error: %v\n", err)
+			continue
+		}
+
+		req := map[string]interface{}{"method": method, "params": params, "id": 1}
+		body, _ := json.Marshal(req)
+		if _, err := conn.Write(append(body, '\n')); err != nil {
+			return fmt.Errorf("could not write to IPC socket: %v", err)
+		}
+
+		resp := bufio.NewReader(conn)
+		line, err := resp.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("could not read from IPC socket: %v", err)
+		}
+		fmt.Fprintln(out, strings.TrimSpace(line))
+	}
+}
+
+// parseCall turns "namespace_method(a, b)" into its method name and raw
+// JSON params.
+func parseCall(line string) (method string, params []json.RawMessage, err error) {
+	line = strings.TrimSpace(line)
+	open := strings.Index(line, "(")
+	if open == -1 || !strings.HasSuffix(line, ")") {
+		return "", nil, fmt.Errorf("expected namespace_method(params...), got %q", line)
+	}
+	method = strings.TrimSpace(line[:open])
+
+	argStr := strings.TrimSpace(line[open+1 : len(line)-1])
+	if argStr == "" {
+		return method, nil, nil
+	}
+	for _, arg := range strings.Split(argStr, ",") {
+		params = append(params, json.RawMessage(strings.TrimSpace(arg)))
+	}
+	return method, params, nil
+}
@@ -7,23 +7,30 @@ package node
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/prysmaticlabs/prysm/client/attester"
 	"github.com/prysmaticlabs/prysm/client/beacon"
 	"github.com/prysmaticlabs/prysm/client/params"
 	"github.com/prysmaticlabs/prysm/client/proposer"
 	"github.com/prysmaticlabs/prysm/client/rpcclient"
+	"github.com/prysmaticlabs/prysm/client/shardstats"
 	"github.com/prysmaticlabs/prysm/client/txpool"
 	"github.com/prysmaticlabs/prysm/client/types"
 	"github.com/prysmaticlabs/prysm/shared"
+	"github.com/prysmaticlabs/prysm/shared/accounts"
 	"github.com/prysmaticlabs/prysm/shared/cmd"
 	"github.com/prysmaticlabs/prysm/shared/database"
 	"github.com/prysmaticlabs/prysm/shared/debug"
+	"github.com/prysmaticlabs/prysm/shared/event"
 	"github.com/prysmaticlabs/prysm/shared/p2p"
+	"github.com/prysmaticlabs/prysm/shared/rpc"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 )
@@ -32,6 +39,11 @@ var log = logrus.WithField("prefix", "node")
 
 const shardChainDBName = "shardchaindata"
 
+// closeDeadline bounds how long Close waits for every service goroutine to
+// join after StopAll returns. Past it, Close gives up, calls debug.Exit and
+// leaves the database open.
+const closeDeadline = 10 * time.Second
+
 // ShardEthereum is a service that is registered and started when geth is launched.
 // it contains APIs and fields that handle the different components of the sharded
 // Ethereum network.
@@ -41,47 +53,83 @@ type ShardEthereum struct {
 	// Lifecycle and service stores.
 	services *shared.ServiceRegistry
 	lock     sync.RWMutex
-	stop     chan struct{} // Channel to wait for termination notifications.
+	stop     chan struct{}      // Channel to wait for termination notifications.
+	cancel   context.CancelFunc // Cancels the root context watched by every service.
 	db       *database.DB
+
+	accounts         *accounts.Manager
+	unlockedAccounts []string // addresses unlocked at startup via --unlock
+
+	// RPC/HTTP subsystem. rpcServer and mux are populated with namespaces
+	// and handlers as services are constructed; startRPC only opens the
+	// listeners once every service has had a chance to register.
+	rpcServer    *rpc.Server
+	mux          *http.ServeMux
+	rpcConfig    rpcConfig
+	httpServer   *http.Server
+	httpListener net.Listener
+	ipcListener  net.Listener
 }
 
 // NewShardInstance creates a new sharding-enabled Ethereum instance. This is called in the main
-// geth sharding entrypoint.
+// geth sharding entrypoint. Services are not constructed here: each is registered as a
+// ServiceConstructor closure and only built once Start runs, so a constructor can pull its
+// dependencies straight out of the ServiceContext instead of fetching them after the fact.
 func NewShardInstance(ctx *cli.Context) (*ShardEthereum, error) {
-	registry := shared.NewServiceRegistry()
-	shardEthereum := &ShardEthereum{
-		services: registry,
-		stop:     make(chan struct{}),
-	}
-
-	// Configure shardConfig by loading the default.
-	shardEthereum.shardConfig = params.DefaultConfig()
-
-	if err := shardEthereum.startDB(ctx); err != nil {
+	path := ctx.GlobalString(cmd.DataDirFlag.Name)
+	db, err := database.NewDB(&database.DBConfig{DataDir: path, Name: shardChainDBName, InMemory: false})
+	if err != nil {
 		return nil, err
 	}
 
-	if err := shardEthereum.registerP2P(); err != nil {
-		return nil, err
+	wg := new(sync.WaitGroup)
+	shardp2p, err := p2p.NewServer(wg)
+	if err != nil {
+		return nil, fmt.Errorf("could not create shardp2p service: %v", err)
 	}
 
-	actorFlag := ctx.GlobalString(types.ActorFlag.Name)
-	if err := shardEthereum.registerTXPool(actorFlag); err != nil {
-		return nil, err
-	}
+	rpcServer := rpc.NewServer()
+	mux := http.NewServeMux()
 
-	if err := shardEthereum.registerRPCClientService(ctx); err != nil {
+	accountManager, unlocked, err := buildAccountManager(ctx, path)
+	if err != nil {
 		return nil, err
 	}
-
-	if err := shardEthereum.registerBeaconService(); err != nil {
-		return nil, err
+	if err := rpcServer.RegisterName("accounts", accounts.NewPublicAPI(accountManager)); err != nil {
+		return nil, fmt.Errorf("could not register accounts API: %v", err)
 	}
 
-	if err := shardEthereum.registerActorService(actorFlag); err != nil {
-		return nil, err
+	shardConfig := params.DefaultConfig()
+	shardEthereum := &ShardEthereum{
+		shardConfig: shardConfig,
+		services: shared.NewServiceRegistry(shared.Dependencies{
+			DB:        db,
+			Config:    shardConfig,
+			EventMux:  event.NewTypeMux(),
+			P2P:       shardp2p,
+			Accounts:  accountManager,
+			WG:        wg,
+			RPCServer: rpcServer,
+			Mux:       mux,
+		}),
+		stop:             make(chan struct{}),
+		db:               db,
+		accounts:         accountManager,
+		unlockedAccounts: unlocked,
+		rpcServer:        rpcServer,
+		mux:              mux,
+		rpcConfig:        newRPCConfig(ctx, path),
 	}
 
+	shardEthereum.registerP2P()
+
+	actorRoles := parseActorRoles(ctx)
+	shardEthereum.registerTXPool(actorRoles)
+	shardEthereum.registerRPCClientService(ctx)
+	shardEthereum.registerBeaconService()
+	shardEthereum.registerActorService(ctx, actorRoles)
+	shardEthereum.registerShardStats(ctx.GlobalString(types.ShardStatsFlag.Name), actorRoles)
+
 	return shardEthereum, nil
 }
 
@@ -91,7 +139,21 @@ func (s *ShardEthereum) Start() {
 
 	log.Info("Starting sharding node")
 
-	s.services.StartAll()
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	if err := s.services.StartAll(ctx); err != nil {
+		log.Errorf("Could not start sharding node: %v", err)
+		cancel()
+		s.lock.Unlock()
+		return
+	}
+
+	// Listeners are opened last, once every service has registered its
+	// namespaces and handlers during construction above.
+	if err := s.startRPC(); err != nil {
+		log.Errorf("Could not start RPC/IPC endpoints: %v", err)
+	}
 
 	stop := s.stop
 	s.lock.Unlock()
@@ -102,6 +164,7 @@ func (s *ShardEthereum) Start() {
 		defer signal.Stop(sigc)
 		<-sigc
 		log.Info("Got interrupt, shutting down...")
+		cancel()
 		go s.Close()
 		for i := 10; i > 0; i-- {
 			<-sigc
@@ -117,94 +180,141 @@ func (s *ShardEthereum) Start() {
 	<-stop
 }
 
-// Close handles graceful shutdown of the system.
+// Close handles graceful shutdown of the system. Services are stopped in
+// reverse-dependency order, each under its own timeout, and Close then
+// joins every goroutine a service registered on the shared WaitGroup
+// before closing the database. A service that never joins within
+// closeDeadline leaves a goroutine that may still be touching the
+// database, so Close leaves the database open rather than closing it out
+// from under that goroutine and risking corruption.
 func (s *ShardEthereum) Close() {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	s.db.Close()
-	s.services.StopAll()
-	log.Info("Stopping sharding node")
+	if s.cancel != nil {
+		s.cancel()
+	}
 
-	close(s.stop)
-}
+	s.stopRPC()
+	s.services.StopAll()
 
-// startDB attaches a LevelDB wrapped object to the shardEthereum instance.
-func (s *ShardEthereum) startDB(ctx *cli.Context) error {
-	path := ctx.GlobalString(cmd.DataDirFlag.Name)
-	config := &database.DBConfig{DataDir: path, Name: shardChainDBName, InMemory: false}
-	db, err := database.NewDB(config)
-	if err != nil {
-		return err
+	deadline, cancel := context.WithTimeout(context.Background(), closeDeadline)
+	defer cancel()
+	if !s.services.Wait(deadline) {
+		log.Error("Timed out waiting for service goroutines to exit, leaving the database open rather than risk corrupting it")
+		debug.Exit() // Ensure trace and CPU profile data are flushed.
+	} else {
+		s.db.Close()
 	}
 
-	s.db = db
-	return nil
+	log.Info("Stopping sharding node")
+
+	close(s.stop)
 }
 
-// registerP2P attaches a p2p server to the ShardEthereum instance.
-func (s *ShardEthereum) registerP2P() error {
-	shardp2p, err := p2p.NewServer()
-	if err != nil {
-		return fmt.Errorf("could not register shardp2p service: %v", err)
-	}
-	return s.services.RegisterService(shardp2p)
+// registerP2P registers the p2p server already created in NewShardInstance as a service so
+// its lifecycle is managed alongside everything else.
+func (s *ShardEthereum) registerP2P() {
+	s.services.Register("p2p", func(ctx *shared.ServiceContext) (shared.Service, error) {
+		return ctx.P2P, nil
+	})
 }
 
-// registerTXPool creates a service that
-// can spin up a transaction pool that will relay incoming transactions via an
-// event feed. For our first releases, this can just relay test/fake transaction data
-// the proposer can serialize into collation blobs.
+// registerTXPool registers a service that can spin up a transaction pool that will relay
+// incoming transactions via an event feed. For our first releases, this can just relay
+// test/fake transaction data the proposer can serialize into collation blobs. It runs
+// whenever a proposer actor is present among roles, regardless of what else runs alongside it.
 // TODO: design this txpool system for our first release.
-func (s *ShardEthereum) registerTXPool(actor string) error {
-	if actor != "proposer" {
-		return nil
+func (s *ShardEthereum) registerTXPool(roles []string) {
+	if !hasActor(roles, "proposer") {
+		return
 	}
-	var shardp2p *p2p.Server
-	if err := s.services.FetchService(&shardp2p); err != nil {
-		return err
-	}
-	pool, err := txpool.NewTXPool(shardp2p)
-	if err != nil {
-		return fmt.Errorf("could not register shard txpool service: %v", err)
-	}
-	return s.services.RegisterService(pool)
+	s.services.Register("txpool", func(ctx *shared.ServiceContext) (shared.Service, error) {
+		return txpool.NewTXPool(ctx.P2P, ctx.WG)
+	})
 }
 
 // registerBeaconService registers a service that fetches streams from a beacon node
 // via RPC.
-func (s *ShardEthereum) registerBeaconService() error {
-	var rpcService *rpcclient.Service
-	if err := s.services.FetchService(&rpcService); err != nil {
-		return err
-	}
-	b := beacon.NewBeaconClient(context.TODO(), beacon.DefaultConfig(), rpcService)
-	return s.services.RegisterService(b)
+func (s *ShardEthereum) registerBeaconService() {
+	s.services.Register("beacon", func(ctx *shared.ServiceContext) (shared.Service, error) {
+		var rpcService *rpcclient.Service
+		if err := ctx.Service(&rpcService); err != nil {
+			return nil, err
+		}
+		b := beacon.NewBeaconClient(beacon.DefaultConfig(), rpcService, ctx.WG)
+		if err := ctx.RegisterAPIs(b.APIs()); err != nil {
+			return nil, err
+		}
+		return b, nil
+	})
 }
 
-// registerActorService registers the actor according to CLI flags. Either attester/proposer.
-func (s *ShardEthereum) registerActorService(actor string) error {
-	var beaconService *beacon.Service
-	if err := s.services.FetchService(&beaconService); err != nil {
-		return err
+// registerActorService registers a constructor for every role in roles, so a single node can
+// host any subset of attester and proposer simultaneously, each on its own goroutines but
+// sharing the same beacon client and account manager. A caller embedding ShardEthereum
+// directly is not limited to this helper: registering an attester or proposer constructor is
+// just a call to s.services.Register, so tests and embedders can compose whichever actor(s)
+// they need without touching this package. Each actor signs with its own address, resolved by
+// resolveSigningAddress from --attester-account/--proposer-account, falling back to the first
+// address named by --unlock; it is left unset, and the actor signs nothing, if neither resolves.
+func (s *ShardEthereum) registerActorService(ctx *cli.Context, roles []string) {
+	attesterAddress := resolveSigningAddress(ctx.GlobalString(types.AttesterAccountFlag.Name), s.unlockedAccounts)
+	proposerAddress := resolveSigningAddress(ctx.GlobalString(types.ProposerAccountFlag.Name), s.unlockedAccounts)
+
+	if hasActor(roles, "attester") {
+		s.services.Register("attester", func(ctx *shared.ServiceContext) (shared.Service, error) {
+			var beaconService *beacon.Service
+			if err := ctx.Service(&beaconService); err != nil {
+				return nil, err
+			}
+			att := attester.NewAttester(beaconService, ctx.Accounts, attesterAddress, ctx.Config.Actor.AttestShards, ctx.WG, ctx.EventMux)
+			if err := ctx.RegisterAPIs(att.APIs()); err != nil {
+				return nil, err
+			}
+			return att, nil
+		})
 	}
 
-	switch actor {
-	case "attester":
-		att := attester.NewAttester(context.TODO(), beaconService)
-		return s.services.RegisterService(att)
-	case "proposer":
-		prop := proposer.NewProposer(context.TODO(), beaconService)
-		return s.services.RegisterService(prop)
+	if hasActor(roles, "proposer") {
+		s.services.Register("proposer", func(ctx *shared.ServiceContext) (shared.Service, error) {
+			var beaconService *beacon.Service
+			if err := ctx.Service(&beaconService); err != nil {
+				return nil, err
+			}
+			prop := proposer.NewProposer(beaconService, ctx.Accounts, proposerAddress, ctx.Config.Actor.ProposeShards, ctx.WG, ctx.EventMux)
+			if err := ctx.RegisterAPIs(prop.APIs()); err != nil {
+				return nil, err
+			}
+			return prop, nil
+		})
 	}
-	return nil
 }
 
 // registerRPCClientService registers a new RPC client that connects to a beacon node.
-func (s *ShardEthereum) registerRPCClientService(ctx *cli.Context) error {
+func (s *ShardEthereum) registerRPCClientService(ctx *cli.Context) {
 	endpoint := ctx.GlobalString(types.BeaconRPCProviderFlag.Name)
-	rpcService := rpcclient.NewRPCClient(context.TODO(), &rpcclient.Config{
-		Endpoint: endpoint,
+	s.services.Register("rpcclient", func(ctx *shared.ServiceContext) (shared.Service, error) {
+		return rpcclient.NewRPCClient(&rpcclient.Config{Endpoint: endpoint}), nil
 	})
-	return s.services.RegisterService(rpcService)
-}
\ No newline at end of file
+}
+
+// registerShardStats registers a telemetry reporter against the configured collector URL.
+// It is a no-op if statsURL is empty, i.e. --shardstats was not passed. roles is the same
+// actor list passed to registerActorService and registerTXPool, so the reporter knows which
+// shards and events to report on without re-deriving it from flags itself.
+func (s *ShardEthereum) registerShardStats(statsURL string, roles []string) {
+	if statsURL == "" {
+		return
+	}
+	s.services.Register("shardstats", func(ctx *shared.ServiceContext) (shared.Service, error) {
+		var beaconService *beacon.Service
+		if err := ctx.Service(&beaconService); err != nil {
+			return nil, err
+		}
+		var txPool *txpool.Service
+		_ = ctx.Service(&txPool) // optional: nil for non-proposer nodes
+
+		return shardstats.New(&shardstats.Config{URL: statsURL}, ctx.Config, roles, ctx.P2P, beaconService, txPool, ctx.EventMux, ctx.WG)
+	})
+}
@@ -0,0 +1,134 @@
+package node
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/prysmaticlabs/prysm/client/types"
+	"github.com/prysmaticlabs/prysm/shared/rpc"
+	"github.com/urfave/cli"
+)
+
+// rpcConfig holds everything startRPC needs to bring up the HTTP and IPC
+// endpoints, gathered from CLI flags at construction time.
+type rpcConfig struct {
+	httpEnabled  bool
+	httpEndpoint string
+	httpAPIs     map[string]bool // nil means every namespace is allowed
+	ipcEndpoint  string          // empty means the IPC server is disabled
+}
+
+func newRPCConfig(ctx *cli.Context, dataDir string) rpcConfig {
+	cfg := rpcConfig{
+		httpEnabled:  ctx.GlobalBool(types.HTTPEnabledFlag.Name),
+		httpEndpoint: fmt.Sprintf("%s:%d", ctx.GlobalString(types.HTTPListenAddrFlag.Name), ctx.GlobalInt(types.HTTPPortFlag.Name)),
+	}
+
+	if apis := ctx.GlobalString(types.HTTPApiFlag.Name); apis != "" {
+		cfg.httpAPIs = make(map[string]bool)
+		for _, ns := range strings.Split(apis, ",") {
+			cfg.httpAPIs[strings.TrimSpace(ns)] = true
+		}
+	}
+
+	if !ctx.GlobalBool(types.IPCDisabledFlag.Name) {
+		cfg.ipcEndpoint = filepath.Join(dataDir, ctx.GlobalString(types.IPCPathFlag.Name))
+	}
+	return cfg
+}
+
+// startRPC brings up the IPC and, if enabled, HTTP JSON-RPC listeners on
+// top of the already-populated rpcServer. Services contribute their
+// namespaces to rpcServer during construction via ServiceContext.RegisterAPIs,
+// so by the time Start runs every API is already registered.
+func (s *ShardEthereum) startRPC() error {
+	if s.rpcConfig.ipcEndpoint != "" {
+		ln, err := net.Listen("unix", s.rpcConfig.ipcEndpoint)
+		if err != nil {
+			return fmt.Errorf("could not start IPC server: %v", err)
+		}
+		s.ipcListener = ln
+		go s.serveIPC(ln)
+		log.Infof("IPC endpoint opened at %s", s.rpcConfig.ipcEndpoint)
+	}
+
+	if s.rpcConfig.httpEnabled {
+		ln, err := net.Listen("tcp", s.rpcConfig.httpEndpoint)
+		if err != nil {
+			return fmt.Errorf("could not start HTTP-RPC server: %v", err)
+		}
+		s.httpListener = ln
+		// Mounted last so services that called ctx.RegisterHandler during
+		// construction keep their own patterns; "/" only now resolves to RPC.
+		s.mux.Handle("/", &apiFilterHandler{allowed: s.rpcConfig.httpAPIs, next: s.rpcServer})
+		s.httpServer = &http.Server{Handler: s.mux}
+		go s.httpServer.Serve(ln)
+		log.Infof("HTTP-RPC endpoint opened at http://%s", s.rpcConfig.httpEndpoint)
+	}
+	return nil
+}
+
+// serveIPC accepts connections on ln and serves each over the raw
+// newline-delimited JSON-RPC framing the IPC socket speaks, distinct from
+// the HTTP framing the TCP endpoint uses. Accept returns an error once
+// stopRPC closes ln, which ends the loop.
+func (s *ShardEthereum) serveIPC(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.rpcServer.ServeConn(conn)
+	}
+}
+
+// stopRPC tears down whichever of the IPC/HTTP listeners were started.
+func (s *ShardEthereum) stopRPC() {
+	if s.ipcListener != nil {
+		s.ipcListener.Close()
+	}
+	if s.httpListener != nil {
+		s.httpListener.Close()
+	}
+}
+
+// apiFilterHandler rejects requests for namespaces not in allowed before
+// delegating to the underlying rpc.Server. allowed == nil permits every
+// namespace, matching --http.api being left unset.
+type apiFilterHandler struct {
+	allowed map[string]bool
+	next    *rpc.Server
+}
+
+func (h *apiFilterHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.allowed == nil {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("could not read request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var peek struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(body, &peek); err == nil {
+		namespace := strings.SplitN(peek.Method, "_", 2)[0]
+		if !h.allowed[namespace] {
+			http.Error(w, fmt.Sprintf("namespace %q not enabled, see --http.api", namespace), http.StatusForbidden)
+			return
+		}
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	h.next.ServeHTTP(w, r)
+}
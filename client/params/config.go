@@ -0,0 +1,28 @@
+// Package params holds chain configuration values for the sharding client.
+package params
+
+// ActorConfig configures which shards this node's actors operate on.
+type ActorConfig struct {
+	// AttestShards lists the shards the attester actor attests on.
+	AttestShards []uint64
+	// ProposeShards lists the shards the proposer actor proposes on.
+	ProposeShards []uint64
+	// CommitteeSource is the RPC endpoint or other source used to resolve
+	// committee assignments for the shards above.
+	CommitteeSource string
+}
+
+// Config holds necessary information to configure shards.
+type Config struct {
+	Actor ActorConfig
+}
+
+// DefaultConfig returns the default shard configuration.
+func DefaultConfig() *Config {
+	return &Config{
+		Actor: ActorConfig{
+			AttestShards:  []uint64{0},
+			ProposeShards: []uint64{0},
+		},
+	}
+}
@@ -0,0 +1,135 @@
+// Package proposer implements the proposer actor, which signs a collation
+// digest for each of its configured shards. It reads no transactions from
+// the txpool and gossips nothing over p2p.
+package proposer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/client/beacon"
+	"github.com/prysmaticlabs/prysm/shared/accounts"
+	"github.com/prysmaticlabs/prysm/shared/event"
+	"github.com/prysmaticlabs/prysm/shared/rpc"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logrus.WithField("prefix", "proposer")
+
+// proposeInterval is how often the main loop checks for a collation to
+// propose on each configured shard, standing in for a real slot clock
+// until one exists.
+const proposeInterval = 6 * time.Second
+
+// ProposedEvent is posted to the node's shared event feed every time this
+// actor successfully signs a proposed collation, so other services (e.g.
+// shardstats) can observe the latest proposed period per shard without
+// polling this actor directly.
+type ProposedEvent struct {
+	ShardID uint64
+	Period  uint64
+}
+
+// Proposer is the actor service responsible for proposing shard
+// collations. It runs independently of any attester actor also registered
+// on the node, so a single node can attest and propose concurrently.
+type Proposer struct {
+	beacon  *beacon.Service
+	account *accounts.Manager
+	address string
+	shards  []uint64
+	wg      *sync.WaitGroup
+	mux     *event.TypeMux
+}
+
+// NewProposer creates a new proposer actor backed by beaconService,
+// signing proposed collations with address out of account. address must
+// already be unlocked before Start is called. shards lists the shard IDs
+// this proposer proposes on. wg is incremented for the lifetime of the
+// proposal loop goroutine Start spawns. mux receives a ProposedEvent for
+// every collation signed.
+func NewProposer(beaconService *beacon.Service, account *accounts.Manager, address string, shards []uint64, wg *sync.WaitGroup, mux *event.TypeMux) *Proposer {
+	return &Proposer{beacon: beaconService, account: account, address: address, shards: shards, wg: wg, mux: mux}
+}
+
+// Start kicks off the proposer's main loop. The loop goroutine watches ctx
+// and exits as soon as it is cancelled.
+func (p *Proposer) Start(ctx context.Context) error {
+	log.Infof("Starting proposer service for shards %v", p.shards)
+	p.wg.Add(1)
+	go p.loop(ctx)
+	return nil
+}
+
+// Stop is a no-op; the proposal loop exits via ctx cancellation and joins
+// on the shared WaitGroup.
+func (p *Proposer) Stop() error {
+	return nil
+}
+
+func (p *Proposer) loop(ctx context.Context) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(proposeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.proposeShards()
+		}
+	}
+}
+
+// proposeShards attempts to sign a proposed collation for every
+// configured shard. It is a no-op if no account was unlocked for this
+// actor.
+func (p *Proposer) proposeShards() {
+	if p.address == "" {
+		return
+	}
+	const period = 0 // TODO: derive from the beacon-reported slot once streaming exists.
+	for _, shard := range p.shards {
+		if _, err := p.signCollation(collationDigest(shard)); err != nil {
+			log.Warnf("shard %d: could not sign proposed collation: %v", shard, err)
+			continue
+		}
+		p.mux.Post(ProposedEvent{ShardID: shard, Period: period})
+	}
+}
+
+// APIs returns the RPC namespaces this service exposes over the node's
+// HTTP/IPC endpoints.
+func (p *Proposer) APIs() []rpc.API {
+	return []rpc.API{
+		{Namespace: "proposer", Version: "1.0", Service: p},
+	}
+}
+
+// SubmitBlob is exposed as proposer_submitBlob. It always returns an
+// error: the blob is not queued and no collation is built from it.
+func (p *Proposer) SubmitBlob(blob string) (bool, error) {
+	return false, fmt.Errorf("proposer collation building not yet implemented")
+}
+
+// signCollation signs a proposed collation's digest with this proposer's
+// unlocked account. proposeShards discards the signature; it is not
+// gossiped over p2p.
+func (p *Proposer) signCollation(collationHash [32]byte) ([]byte, error) {
+	digest := sha256.Sum256(collationHash[:])
+	return p.account.Sign(p.address, digest[:])
+}
+
+// collationDigest derives a placeholder digest for shard's next
+// collation, to be signed by signCollation until real collation building
+// exists.
+func collationDigest(shard uint64) [32]byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, shard)
+	return sha256.Sum256(buf)
+}
@@ -0,0 +1,39 @@
+// Package rpcclient maintains the gRPC connection a shard client uses to
+// talk to a beacon node.
+package rpcclient
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+var log = logrus.WithField("prefix", "rpcclient")
+
+// Config configures the beacon node endpoint this client dials.
+type Config struct {
+	Endpoint string
+}
+
+// Service holds the beacon node endpoint a shard client connects to. It
+// opens no connection.
+type Service struct {
+	config *Config
+}
+
+// NewRPCClient configures a new beacon RPC client service for
+// config.Endpoint.
+func NewRPCClient(config *Config) *Service {
+	return &Service{config: config}
+}
+
+// Start logs the configured beacon node endpoint; it dials no connection.
+func (s *Service) Start(ctx context.Context) error {
+	log.Infof("Connecting to beacon RPC provider at %s", s.config.Endpoint)
+	return nil
+}
+
+// Stop is a no-op; there is no connection to tear down.
+func (s *Service) Stop() error {
+	return nil
+}
@@ -0,0 +1,322 @@
+// Package shardstats implements an ethstats-style telemetry reporter: a
+// service that opens a websocket to a stats collector and periodically
+// reports this shard node's state (roles, peer count, sync head per
+// shard, last attested/proposed period, pending transactions, memory
+// usage) so operators running many nodes get a single dashboard instead
+// of scraping each one individually.
+package shardstats
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/client/attester"
+	"github.com/prysmaticlabs/prysm/client/beacon"
+	"github.com/prysmaticlabs/prysm/client/params"
+	"github.com/prysmaticlabs/prysm/client/proposer"
+	"github.com/prysmaticlabs/prysm/client/txpool"
+	"github.com/prysmaticlabs/prysm/shared/event"
+	"github.com/prysmaticlabs/prysm/shared/p2p"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/websocket"
+)
+
+var log = logrus.WithField("prefix", "shardstats")
+
+const (
+	reportInterval = 15 * time.Second
+	initialBackoff = 1 * time.Second
+	maxBackoff     = 1 * time.Minute
+)
+
+// Config configures the stats collector endpoint this service reports to.
+type Config struct {
+	// URL is of the form "nodename:secret@host:port", matching the
+	// endpoint format used by ethstats collectors.
+	URL string
+}
+
+// Service periodically reports shard node telemetry to a stats collector.
+type Service struct {
+	nodename string
+	secret   string
+	endpoint string
+
+	roles    []string
+	shardIDs []uint64
+
+	p2p    *p2p.Server
+	beacon *beacon.Service
+	txpool *txpool.Service // nil unless this node runs a proposer
+	mux    *event.TypeMux
+
+	mu           sync.Mutex
+	lastAttested map[uint64]uint64
+	lastProposed map[uint64]uint64
+
+	startedAt time.Time
+	wg        *sync.WaitGroup
+}
+
+// New creates a shardstats reporter for a node running roles (as resolved
+// by the node's --actor flag) against shardConfig. txPool may be nil for
+// nodes that do not run a proposer. mux is the node's shared event feed:
+// once Start runs, the service subscribes to it for
+// AttestedEvent/ProposedEvent instead of polling the attester/proposer
+// actors directly.
+func New(config *Config, shardConfig *params.Config, roles []string, p2pServer *p2p.Server, beaconService *beacon.Service, txPool *txpool.Service, mux *event.TypeMux, wg *sync.WaitGroup) (*Service, error) {
+	nodename, secret, endpoint, err := parseURL(config.URL)
+	if err != nil {
+		return nil, err
+	}
+	return &Service{
+		nodename:     nodename,
+		secret:       secret,
+		endpoint:     endpoint,
+		roles:        roles,
+		shardIDs:     reportedShards(shardConfig, roles),
+		p2p:          p2pServer,
+		beacon:       beaconService,
+		txpool:       txPool,
+		mux:          mux,
+		lastAttested: make(map[uint64]uint64),
+		lastProposed: make(map[uint64]uint64),
+		wg:           wg,
+	}, nil
+}
+
+// reportedShards returns the deduplicated union of shards this node's
+// actors operate on, in first-seen order, so the reporter knows which
+// shard(s) to report a sync head for.
+func reportedShards(shardConfig *params.Config, roles []string) []uint64 {
+	seen := make(map[uint64]bool)
+	var shards []uint64
+	add := func(ids []uint64) {
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				shards = append(shards, id)
+			}
+		}
+	}
+	for _, role := range roles {
+		switch role {
+		case "attester":
+			add(shardConfig.Actor.AttestShards)
+		case "proposer":
+			add(shardConfig.Actor.ProposeShards)
+		}
+	}
+	return shards
+}
+
+// parseURL splits "nodename:secret@host:port" into its parts.
+func parseURL(raw string) (nodename, secret, endpoint string, err error) {
+	parts := strings.SplitN(raw, "@", 2)
+	if len(parts) != 2 {
+		return "", "", "", fmt.Errorf("malformed --shardstats endpoint %q, expected nodename:secret@host:port", raw)
+	}
+	cred := strings.SplitN(parts[0], ":", 2)
+	if len(cred) != 2 {
+		return "", "", "", fmt.Errorf("malformed --shardstats endpoint %q, expected nodename:secret@host:port", raw)
+	}
+	return cred[0], cred[1], parts[1], nil
+}
+
+// Start launches the reconnect loop and the event tracker. Both watch ctx
+// for cancellation and exit once it is done.
+func (s *Service) Start(ctx context.Context) error {
+	s.startedAt = time.Now()
+	s.wg.Add(2)
+	go s.trackEvents(ctx)
+	go s.loop(ctx)
+	return nil
+}
+
+// Stop is a no-op; both of Start's goroutines exit via ctx cancellation
+// and join on the shared WaitGroup.
+func (s *Service) Stop() error {
+	return nil
+}
+
+// trackEvents subscribes to the node's shared event feed and keeps the
+// latest attested/proposed period per shard, so collect reports it
+// without ever calling back into the attester/proposer services.
+func (s *Service) trackEvents(ctx context.Context) {
+	defer s.wg.Done()
+
+	attested := s.mux.Subscribe(attester.AttestedEvent{})
+	proposed := s.mux.Subscribe(proposer.ProposedEvent{})
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-attested:
+			if !ok {
+				return
+			}
+			if e, ok := ev.(attester.AttestedEvent); ok {
+				s.mu.Lock()
+				s.lastAttested[e.ShardID] = e.Period
+				s.mu.Unlock()
+			}
+		case ev, ok := <-proposed:
+			if !ok {
+				return
+			}
+			if e, ok := ev.(proposer.ProposedEvent); ok {
+				s.mu.Lock()
+				s.lastProposed[e.ShardID] = e.Period
+				s.mu.Unlock()
+			}
+		}
+	}
+}
+
+// loop dials the collector, reports on reportInterval until the
+// connection drops or ctx is cancelled, and reconnects with exponential
+// backoff on failure.
+func (s *Service) loop(ctx context.Context) {
+	defer s.wg.Done()
+
+	backoff := initialBackoff
+	for {
+		conn, err := s.dial()
+		if err != nil {
+			log.Warnf("Could not connect to stats collector: %v, retrying in %s", err, backoff)
+			select {
+			case <-time.After(backoff):
+				backoff = nextBackoff(backoff)
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		backoff = initialBackoff
+		log.Infof("Connected to stats collector at %s", s.endpoint)
+		s.report(ctx, conn)
+		conn.Close()
+
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+	}
+}
+
+func (s *Service) dial() (*websocket.Conn, error) {
+	origin := "http://" + s.nodename
+	wsURL := fmt.Sprintf("ws://%s/api", s.endpoint)
+	if _, err := url.Parse(wsURL); err != nil {
+		return nil, err
+	}
+	return websocket.Dial(wsURL, "", origin)
+}
+
+type loginMsg struct {
+	Nodename string `json:"nodename"`
+	Secret   string `json:"secret"`
+}
+
+// report authenticates with the collector, then sends a telemetry
+// snapshot every reportInterval until the connection errors or ctx is
+// cancelled.
+func (s *Service) report(ctx context.Context, conn *websocket.Conn) {
+	if err := websocket.JSON.Send(conn, loginMsg{Nodename: s.nodename, Secret: s.secret}); err != nil {
+		log.Warnf("Could not authenticate with stats collector: %v", err)
+		return
+	}
+
+	ticker := time.NewTicker(reportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stats := s.collect()
+			if err := websocket.JSON.Send(conn, stats); err != nil {
+				log.Warnf("Could not report stats: %v", err)
+				return
+			}
+		}
+	}
+}
+
+type nodeStats struct {
+	ID            string            `json:"id"`
+	Roles         []string          `json:"roles"`
+	Peers         int               `json:"peers"`
+	TxPoolPending int               `json:"txpoolPending,omitempty"`
+	Goroutines    int               `json:"goroutines"`
+	UptimeSeconds int               `json:"uptimeSeconds"`
+	AllocBytes    uint64            `json:"allocBytes"`
+	ShardHeads    map[uint64]string `json:"shardHeads,omitempty"`
+	LastAttested  map[uint64]uint64 `json:"lastAttested,omitempty"`
+	LastProposed  map[uint64]uint64 `json:"lastProposed,omitempty"`
+}
+
+// collect snapshots the node's current telemetry. ShardHeads is queried
+// directly from the beacon client (it has no event source yet);
+// LastAttested/LastProposed instead come from trackEvents, since the
+// attester/proposer actors already post those as events.
+func (s *Service) collect() nodeStats {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	heads := make(map[uint64]string, len(s.shardIDs))
+	for _, shard := range s.shardIDs {
+		if head, err := s.beacon.GetCollation(shard, 0); err == nil {
+			heads[shard] = head
+		}
+	}
+
+	pending := 0
+	if s.txpool != nil {
+		pending = s.txpool.Pending()
+	}
+
+	s.mu.Lock()
+	lastAttested := copyPeriods(s.lastAttested)
+	lastProposed := copyPeriods(s.lastProposed)
+	s.mu.Unlock()
+
+	return nodeStats{
+		ID:            s.nodename,
+		Roles:         s.roles,
+		Peers:         s.p2p.PeerCount(),
+		TxPoolPending: pending,
+		Goroutines:    runtime.NumGoroutine(),
+		UptimeSeconds: int(time.Since(s.startedAt).Seconds()),
+		AllocBytes:    mem.Alloc,
+		ShardHeads:    heads,
+		LastAttested:  lastAttested,
+		LastProposed:  lastProposed,
+	}
+}
+
+func copyPeriods(m map[uint64]uint64) map[uint64]uint64 {
+	out := make(map[uint64]uint64, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// nextBackoff doubles d, capped at maxBackoff.
+func nextBackoff(d time.Duration) time.Duration {
+	d *= 2
+	if d > maxBackoff {
+		return maxBackoff
+	}
+	return d
+}
@@ -0,0 +1,56 @@
+// Package txpool relays incoming shard transactions so a proposer can
+// serialize them into collation blobs.
+//
+// TODO: design this txpool system for our first release.
+package txpool
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prysmaticlabs/prysm/shared/p2p"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logrus.WithField("prefix", "txpool")
+
+// Service holds the transactions pending for a proposer. Nothing adds to
+// the pending list, so Pending always reports zero.
+type Service struct {
+	p2p *p2p.Server
+	wg  *sync.WaitGroup
+
+	mu      sync.Mutex
+	pending []string // TODO: real transaction type once relaying is implemented.
+}
+
+// NewTXPool creates a new transaction pool service backed by shardp2p. wg
+// is incremented for the lifetime of the goroutine Start spawns.
+func NewTXPool(shardp2p *p2p.Server, wg *sync.WaitGroup) (*Service, error) {
+	return &Service{p2p: shardp2p, wg: wg}, nil
+}
+
+// Start spawns a goroutine that exits as soon as ctx is cancelled. It does
+// not listen for transactions over p2p.
+func (s *Service) Start(ctx context.Context) error {
+	log.Info("Starting shard txpool service")
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		<-ctx.Done()
+	}()
+	return nil
+}
+
+// Stop is a no-op; there is no transaction feed listener to halt.
+func (s *Service) Stop() error {
+	return nil
+}
+
+// Pending returns the number of transactions relayed but not yet included
+// in a proposed collation.
+func (s *Service) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pending)
+}
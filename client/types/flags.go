@@ -0,0 +1,107 @@
+// Package types defines CLI flags and shared value types for the sharding
+// client.
+package types
+
+import "github.com/urfave/cli"
+
+// ActorFlag specifies which actor role(s) the node should run, repeatable
+// (--actor=attester --actor=proposer) or comma separated
+// (--actor=attester,proposer), so a single node can host any subset of
+// attester and proposer simultaneously.
+var ActorFlag = cli.StringSliceFlag{
+	Name:  "actor",
+	Usage: "Actor service(s) to register (attester, proposer), repeatable or comma separated",
+}
+
+// BeaconRPCProviderFlag specifies the RPC endpoint of the beacon node this
+// shard client connects to.
+var BeaconRPCProviderFlag = cli.StringFlag{
+	Name:  "beacon-rpc",
+	Usage: "RPC endpoint of a beacon node to connect to",
+	Value: "localhost:4000",
+}
+
+// HTTPEnabledFlag enables the JSON-RPC HTTP endpoint.
+var HTTPEnabledFlag = cli.BoolFlag{
+	Name:  "http",
+	Usage: "Enable the HTTP-RPC server",
+}
+
+// HTTPListenAddrFlag sets the listening interface for the HTTP-RPC server.
+var HTTPListenAddrFlag = cli.StringFlag{
+	Name:  "http.addr",
+	Usage: "HTTP-RPC server listening interface",
+	Value: "localhost",
+}
+
+// HTTPPortFlag sets the listening port for the HTTP-RPC server.
+var HTTPPortFlag = cli.IntFlag{
+	Name:  "http.port",
+	Usage: "HTTP-RPC server listening port",
+	Value: 4040,
+}
+
+// HTTPApiFlag restricts the HTTP-RPC server to the given comma separated
+// list of namespaces. An empty value exposes every registered namespace.
+var HTTPApiFlag = cli.StringFlag{
+	Name:  "http.api",
+	Usage: "API namespaces to expose on the HTTP-RPC server",
+	Value: "",
+}
+
+// IPCDisabledFlag disables the IPC-RPC server.
+var IPCDisabledFlag = cli.BoolFlag{
+	Name:  "ipcdisable",
+	Usage: "Disable the IPC-RPC server",
+}
+
+// IPCPathFlag overrides the filename of the IPC socket, created by
+// default as "shard.ipc" inside the data directory.
+var IPCPathFlag = cli.StringFlag{
+	Name:  "ipcpath",
+	Usage: "Filename for the IPC socket/pipe within the data dir",
+	Value: "shard.ipc",
+}
+
+// ShardStatsFlag reports this node's telemetry to an ethstats-style
+// collector at nodename:secret@host:port. Empty disables reporting.
+var ShardStatsFlag = cli.StringFlag{
+	Name:  "shardstats",
+	Usage: "Reporting URL of a shardstats collector (nodename:secret@host:port)",
+	Value: "",
+}
+
+// UnlockFlag is a comma separated list of keystore addresses to unlock at
+// startup, so the actor services registered for this node can sign with
+// them.
+var UnlockFlag = cli.StringFlag{
+	Name:  "unlock",
+	Usage: "Comma separated list of keystore addresses to unlock",
+	Value: "",
+}
+
+// AttesterAccountFlag selects which of the addresses named by --unlock the
+// attester actor signs with. Defaults to the first --unlock address,
+// matching a single-actor, single-account node.
+var AttesterAccountFlag = cli.StringFlag{
+	Name:  "attester-account",
+	Usage: "Unlocked address the attester actor signs with (default: first --unlock address)",
+	Value: "",
+}
+
+// ProposerAccountFlag selects which of the addresses named by --unlock the
+// proposer actor signs with. Defaults to the first --unlock address,
+// matching a single-actor, single-account node.
+var ProposerAccountFlag = cli.StringFlag{
+	Name:  "proposer-account",
+	Usage: "Unlocked address the proposer actor signs with (default: first --unlock address)",
+	Value: "",
+}
+
+// PasswordFileFlag points at a file with one passphrase per line,
+// matched positionally against the addresses in --unlock.
+var PasswordFileFlag = cli.StringFlag{
+	Name:  "password",
+	Usage: "Password file to read passphrases for --unlock from",
+	Value: "",
+}
@@ -0,0 +1,322 @@
+// Package accounts implements an encrypted keystore for the signing keys
+// attester and proposer actors use to sign attestations and proposals,
+// modeled on go-ethereum's accounts.Manager.
+package accounts
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// Account identifies a signing key in the keystore by its derived address
+// and carries the public key that Manager.Verify checks signatures by it
+// against.
+type Account struct {
+	Address   string `json:"address"`
+	PublicKey string `json:"publicKey"`
+	File      string `json:"-"`
+}
+
+type cryptoJSON struct {
+	CipherText string `json:"ciphertext"`
+	IV         string `json:"iv"`
+	Salt       string `json:"salt"`
+	MAC        string `json:"mac"`
+}
+
+type encryptedKeyJSON struct {
+	Address   string     `json:"address"`
+	PublicKey string     `json:"publicKey"`
+	Crypto    cryptoJSON `json:"crypto"`
+}
+
+// Manager owns a keystore directory of passphrase-encrypted ed25519
+// signing keys and keeps a private key in memory, keyed by address, once
+// unlocked.
+type Manager struct {
+	mu          sync.Mutex
+	keystoreDir string
+	accounts    []Account
+	unlocked    map[string]ed25519.PrivateKey
+}
+
+// NewManager opens (creating if necessary) the keystore directory and
+// indexes whatever key files are already in it.
+func NewManager(keystoreDir string) (*Manager, error) {
+	if err := os.MkdirAll(keystoreDir, 0700); err != nil {
+		return nil, fmt.Errorf("could not create keystore dir: %v", err)
+	}
+	m := &Manager{keystoreDir: keystoreDir, unlocked: make(map[string]ed25519.PrivateKey)}
+	if err := m.scan(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *Manager) scan() error {
+	entries, err := ioutil.ReadDir(m.keystoreDir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(m.keystoreDir, entry.Name())
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var key encryptedKeyJSON
+		if err := json.Unmarshal(data, &key); err != nil {
+			continue
+		}
+		m.accounts = append(m.accounts, Account{Address: key.Address, PublicKey: key.PublicKey, File: path})
+	}
+	return nil
+}
+
+// List returns every account currently in the keystore.
+func (m *Manager) List() ([]Account, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make([]Account, len(m.accounts))
+	copy(out, m.accounts)
+	return out, nil
+}
+
+// New generates an ed25519 signing key, encrypts it with passphrase, and
+// writes it to the keystore as a new UTC--<address> file.
+func (m *Manager) New(passphrase string) (Account, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return Account{}, err
+	}
+
+	sum := sha256.Sum256(pub)
+	address := hex.EncodeToString(sum[:20])
+	publicKey := hex.EncodeToString(pub)
+
+	enc, err := encrypt(priv, passphrase)
+	if err != nil {
+		return Account{}, err
+	}
+
+	account := Account{
+		Address:   address,
+		PublicKey: publicKey,
+		File:      filepath.Join(m.keystoreDir, fmt.Sprintf("UTC--%s", address)),
+	}
+	data, err := json.Marshal(encryptedKeyJSON{Address: address, PublicKey: publicKey, Crypto: *enc})
+	if err != nil {
+		return Account{}, err
+	}
+	if err := ioutil.WriteFile(account.File, data, 0600); err != nil {
+		return Account{}, err
+	}
+
+	m.mu.Lock()
+	m.accounts = append(m.accounts, account)
+	m.mu.Unlock()
+	return account, nil
+}
+
+// Unlock decrypts address's key file with passphrase and holds the
+// private key in memory so Sign can be called for it.
+func (m *Manager) Unlock(address, passphrase string) (bool, error) {
+	m.mu.Lock()
+	var file string
+	for _, a := range m.accounts {
+		if a.Address == address {
+			file = a.File
+			break
+		}
+	}
+	m.mu.Unlock()
+	if file == "" {
+		return false, fmt.Errorf("no account %s in keystore %s", address, m.keystoreDir)
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return false, err
+	}
+	var key encryptedKeyJSON
+	if err := json.Unmarshal(data, &key); err != nil {
+		return false, err
+	}
+
+	priv, err := decrypt(&key.Crypto, passphrase)
+	if err != nil {
+		return false, fmt.Errorf("could not decrypt account %s: %v", address, err)
+	}
+
+	m.mu.Lock()
+	m.unlocked[address] = ed25519.PrivateKey(priv)
+	m.mu.Unlock()
+	return true, nil
+}
+
+// Sign signs digest with address's unlocked private key, returning an
+// asymmetric ed25519 signature that can be checked against the account's
+// public key without the private key itself. It returns an error if
+// address has not been unlocked.
+func (m *Manager) Sign(address string, digest []byte) ([]byte, error) {
+	m.mu.Lock()
+	priv, ok := m.unlocked[address]
+	m.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("account %s is locked", address)
+	}
+
+	return ed25519.Sign(priv, digest), nil
+}
+
+// Verify reports whether sig is a valid signature over digest from
+// address's public key. Unlike Sign, it needs no unlocked private key, but
+// address must be an account in this manager's keystore; for any other
+// address it returns an error.
+func (m *Manager) Verify(address string, digest, sig []byte) (bool, error) {
+	m.mu.Lock()
+	var publicKey string
+	for _, a := range m.accounts {
+		if a.Address == address {
+			publicKey = a.PublicKey
+			break
+		}
+	}
+	m.mu.Unlock()
+	if publicKey == "" {
+		return false, fmt.Errorf("no account %s in keystore %s", address, m.keystoreDir)
+	}
+
+	pub, err := hex.DecodeString(publicKey)
+	if err != nil {
+		return false, fmt.Errorf("corrupt public key for account %s: %v", address, err)
+	}
+	return ed25519.Verify(ed25519.PublicKey(pub), digest, sig), nil
+}
+
+// PublicAPI is the subset of Manager's methods safe to expose over the
+// node's JSON-RPC surface. Sign and Verify are deliberately not part of
+// it: registering Manager itself would publish Sign over RPC and turn any
+// unlocked validator key into a remote signing oracle for arbitrary
+// attacker-chosen digests.
+type PublicAPI struct {
+	manager *Manager
+}
+
+// NewPublicAPI wraps manager for RPC registration under the "accounts"
+// namespace.
+func NewPublicAPI(manager *Manager) *PublicAPI {
+	return &PublicAPI{manager: manager}
+}
+
+// List returns every account currently in the keystore.
+func (api *PublicAPI) List() ([]Account, error) {
+	return api.manager.List()
+}
+
+// New generates a new signing key encrypted with passphrase.
+func (api *PublicAPI) New(passphrase string) (Account, error) {
+	return api.manager.New(passphrase)
+}
+
+// Unlock decrypts address's key file with passphrase so the node's actor
+// services can sign with it.
+func (api *PublicAPI) Unlock(address, passphrase string) (bool, error) {
+	return api.manager.Unlock(address, passphrase)
+}
+
+// encrypt derives a key from passphrase with scrypt and encrypts priv
+// with AES-CTR, authenticated with an HMAC-SHA256 over the ciphertext.
+func encrypt(priv []byte, passphrase string) (*cryptoJSON, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	derived, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, 64)
+	if err != nil {
+		return nil, err
+	}
+	encKey, macKey := derived[:32], derived[32:]
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext := make([]byte, len(priv))
+	cipher.NewCTR(block, iv).XORKeyStream(ciphertext, priv)
+
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write(ciphertext)
+
+	return &cryptoJSON{
+		CipherText: hex.EncodeToString(ciphertext),
+		IV:         hex.EncodeToString(iv),
+		Salt:       hex.EncodeToString(salt),
+		MAC:        hex.EncodeToString(mac.Sum(nil)),
+	}, nil
+}
+
+func decrypt(c *cryptoJSON, passphrase string) ([]byte, error) {
+	salt, err := hex.DecodeString(c.Salt)
+	if err != nil {
+		return nil, err
+	}
+	derived, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, 64)
+	if err != nil {
+		return nil, err
+	}
+	encKey, macKey := derived[:32], derived[32:]
+
+	ciphertext, err := hex.DecodeString(c.CipherText)
+	if err != nil {
+		return nil, err
+	}
+	wantMAC, err := hex.DecodeString(c.MAC)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write(ciphertext)
+	if !hmac.Equal(mac.Sum(nil), wantMAC) {
+		return nil, fmt.Errorf("invalid passphrase")
+	}
+
+	iv, err := hex.DecodeString(c.IV)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+	plain := make([]byte, len(ciphertext))
+	cipher.NewCTR(block, iv).XORKeyStream(plain, ciphertext)
+	return plain, nil
+}
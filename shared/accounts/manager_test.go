@@ -0,0 +1,100 @@
+package accounts
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func withKeystore(t *testing.T) (*Manager, func()) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatalf("could not create temp keystore dir: %v", err)
+	}
+	manager, err := NewManager(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	return manager, func() { os.RemoveAll(dir) }
+}
+
+func TestNewUnlockSignVerify(t *testing.T) {
+	manager, cleanup := withKeystore(t)
+	defer cleanup()
+
+	account, err := manager.New("passphrase")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	digest := []byte("attest shard 0 period 1")
+
+	if _, err := manager.Sign(account.Address, digest); err == nil {
+		t.Fatal("Sign() on a locked account should fail")
+	}
+
+	if ok, err := manager.Unlock(account.Address, "passphrase"); err != nil || !ok {
+		t.Fatalf("Unlock() = %v, %v, want true, nil", ok, err)
+	}
+
+	sig, err := manager.Sign(account.Address, digest)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	valid, err := manager.Verify(account.Address, digest, sig)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !valid {
+		t.Fatal("Verify() = false for a signature produced by Sign()")
+	}
+
+	if valid, err := manager.Verify(account.Address, []byte("a different message"), sig); err != nil || valid {
+		t.Fatalf("Verify() = %v, %v on a tampered digest, want false, nil", valid, err)
+	}
+}
+
+func TestUnlockWrongPassphrase(t *testing.T) {
+	manager, cleanup := withKeystore(t)
+	defer cleanup()
+
+	account, err := manager.New("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if ok, err := manager.Unlock(account.Address, "wrong passphrase"); err == nil || ok {
+		t.Fatalf("Unlock() with the wrong passphrase = %v, %v, want false, error", ok, err)
+	}
+}
+
+func TestManagerScansExistingKeystore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatalf("could not create temp keystore dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	first, err := NewManager(dir)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	account, err := first.New("passphrase")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	second, err := NewManager(dir)
+	if err != nil {
+		t.Fatalf("NewManager() on a reopened keystore error = %v", err)
+	}
+	accounts, err := second.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(accounts) != 1 || accounts[0].Address != account.Address || accounts[0].PublicKey != account.PublicKey {
+		t.Fatalf("List() = %+v, want the single account created above", accounts)
+	}
+}
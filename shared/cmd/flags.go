@@ -0,0 +1,12 @@
+// Package cmd defines CLI flags shared across the sharding client binaries.
+package cmd
+
+import "github.com/urfave/cli"
+
+// DataDirFlag specifies the directory holding the shard node's chain data,
+// keystore, and IPC socket.
+var DataDirFlag = cli.StringFlag{
+	Name:  "datadir",
+	Usage: "Data directory for the shard node",
+	Value: "",
+}
@@ -0,0 +1,26 @@
+// Package database wraps the on-disk LevelDB instance used to persist
+// shard chain data.
+package database
+
+// DBConfig configures where and how the database is opened.
+type DBConfig struct {
+	DataDir  string
+	Name     string
+	InMemory bool
+}
+
+// DB holds the configuration of the shard chain database. It opens no
+// LevelDB handle.
+type DB struct {
+	config *DBConfig
+}
+
+// NewDB returns a DB for config without touching the disk.
+func NewDB(config *DBConfig) (*DB, error) {
+	return &DB{config: config}, nil
+}
+
+// Close is a no-op; there is no underlying handle to flush or close.
+func (db *DB) Close() error {
+	return nil
+}
@@ -0,0 +1,6 @@
+// Package debug exposes process introspection helpers (pprof, tracing)
+// used by the sharding client binaries.
+package debug
+
+// Exit flushes any in-flight CPU/trace profiles before the process exits.
+func Exit() {}
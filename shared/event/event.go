@@ -0,0 +1,74 @@
+// Package event implements a simple publish-subscribe event feed used to
+// decouple services that produce state changes (new peers, new blobs, a
+// synced beacon head) from services that merely want to observe them.
+package event
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// ErrMuxClosed is returned by Post once the mux has been stopped.
+var ErrMuxClosed = errors.New("event: mux closed")
+
+// TypeMux dispatches events to subscribers by the concrete type of the
+// posted value. It is safe for concurrent use.
+type TypeMux struct {
+	mu      sync.RWMutex
+	subs    map[reflect.Type][]chan interface{}
+	stopped bool
+}
+
+// NewTypeMux creates a ready to use TypeMux.
+func NewTypeMux() *TypeMux {
+	return &TypeMux{subs: make(map[reflect.Type][]chan interface{})}
+}
+
+// Subscribe returns a channel that receives every value posted whose
+// concrete type matches one of the given samples.
+func (mux *TypeMux) Subscribe(types ...interface{}) chan interface{} {
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+
+	ch := make(chan interface{}, 16)
+	for _, t := range types {
+		rt := reflect.TypeOf(t)
+		mux.subs[rt] = append(mux.subs[rt], ch)
+	}
+	return ch
+}
+
+// Post delivers event to every subscriber registered for its type. It
+// returns ErrMuxClosed if the mux has been stopped, and is a no-op if
+// nobody is listening.
+func (mux *TypeMux) Post(event interface{}) error {
+	mux.mu.RLock()
+	defer mux.mu.RUnlock()
+
+	if mux.stopped {
+		return ErrMuxClosed
+	}
+	for _, ch := range mux.subs[reflect.TypeOf(event)] {
+		select {
+		case ch <- event:
+		default:
+			// Slow subscriber, drop the event rather than block the poster.
+		}
+	}
+	return nil
+}
+
+// Stop closes the mux. Subsequent calls to Post return ErrMuxClosed.
+func (mux *TypeMux) Stop() {
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+
+	mux.stopped = true
+	for _, chs := range mux.subs {
+		for _, ch := range chs {
+			close(ch)
+		}
+	}
+	mux.subs = nil
+}
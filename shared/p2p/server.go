@@ -0,0 +1,42 @@
+// Package p2p provides the peer-to-peer networking layer shared by shard
+// client services.
+package p2p
+
+import (
+	"context"
+	"sync"
+)
+
+// Server is the p2p service of a shard node. It runs no peer discovery or
+// message gossip, so PeerCount reports zero.
+type Server struct {
+	wg        *sync.WaitGroup
+	peerCount int
+}
+
+// NewServer creates a p2p server. wg is incremented for the lifetime of the
+// goroutine Start spawns, so callers can join it during shutdown.
+func NewServer(wg *sync.WaitGroup) (*Server, error) {
+	return &Server{wg: wg}, nil
+}
+
+// Start spawns a goroutine that exits as soon as ctx is cancelled. It opens
+// no listener and relays no gossip.
+func (s *Server) Start(ctx context.Context) error {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		<-ctx.Done()
+	}()
+	return nil
+}
+
+// Stop is a no-op; there is no listener or peer connection to tear down.
+func (s *Server) Stop() error {
+	return nil
+}
+
+// PeerCount returns the number of currently connected peers.
+func (s *Server) PeerCount() int {
+	return s.peerCount
+}
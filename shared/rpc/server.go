@@ -0,0 +1,191 @@
+// Package rpc implements a minimal JSON-RPC server, shared by the HTTP and
+// IPC endpoints the node exposes. Services register themselves under a
+// namespace; every exported method becomes callable as
+// "<namespace>_<lowerCamelMethod>".
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// API describes a single RPC namespace contributed by a service.
+type API struct {
+	Namespace string      // e.g. "shard", "proposer", "attester"
+	Version   string      // semantic version of the namespace, e.g. "1.0"
+	Service   interface{} // receiver whose exported methods are dispatched to
+}
+
+// Server dispatches incoming JSON-RPC requests to the services registered
+// under each namespace. It is safe for concurrent use and can be served
+// over both HTTP and a Unix IPC socket.
+type Server struct {
+	mu       sync.RWMutex
+	services map[string]reflect.Value
+}
+
+// NewServer creates an empty RPC server. Use RegisterName to add
+// namespaces before serving requests.
+func NewServer() *Server {
+	return &Server{services: make(map[string]reflect.Value)}
+}
+
+// RegisterName exposes every exported method on service under namespace.
+func (s *Server) RegisterName(namespace string, service interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.services[namespace]; exists {
+		return fmt.Errorf("namespace %q already registered", namespace)
+	}
+	s.services[namespace] = reflect.ValueOf(service)
+	return nil
+}
+
+type request struct {
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+	ID     json.RawMessage   `json:"id"`
+}
+
+type response struct {
+	Result interface{}     `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	ID     json.RawMessage `json:"id,omitempty"`
+}
+
+// ServeHTTP implements http.Handler, so a Server can be mounted directly on
+// an *http.ServeMux. The IPC socket is served with ServeConn instead.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, response{Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+
+	result, err := s.call(req.Method, req.Params)
+	resp := response{ID: req.ID, Result: result}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	writeJSON(w, resp)
+}
+
+// ServeConn serves newline-delimited JSON-RPC requests read from conn, one
+// response per request line, until a read fails or conn is closed. This is
+// what the IPC listener uses: the IPC socket speaks raw JSON-RPC framing,
+// not HTTP, so it cannot be served with ServeHTTP the way the TCP endpoint
+// is.
+func (s *Server) ServeConn(conn net.Conn) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var req request
+		var resp response
+		if err := json.Unmarshal(line, &req); err != nil {
+			resp = response{Error: fmt.Sprintf("invalid request: %v", err)}
+		} else {
+			result, err := s.call(req.Method, req.Params)
+			resp = response{ID: req.ID, Result: result}
+			if err != nil {
+				resp.Error = err.Error()
+			}
+		}
+
+		body, err := json.Marshal(resp)
+		if err != nil {
+			return
+		}
+		if _, err := conn.Write(append(body, '\n')); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) call(method string, params []json.RawMessage) (interface{}, error) {
+	namespace, methodName, err := splitMethod(method)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	receiver, ok := s.services[namespace]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown namespace %q", namespace)
+	}
+
+	fn := receiver.MethodByName(methodName)
+	if !fn.IsValid() {
+		return nil, fmt.Errorf("unknown method %q on namespace %q", methodName, namespace)
+	}
+
+	in, err := decodeArgs(fn, params)
+	if err != nil {
+		return nil, err
+	}
+
+	out := fn.Call(in)
+	return unpackResult(out)
+}
+
+// splitMethod turns "shard_getCollation" into ("shard", "GetCollation").
+func splitMethod(method string) (namespace, methodName string, err error) {
+	parts := strings.SplitN(method, "_", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return "", "", fmt.Errorf("malformed method %q, expected namespace_method", method)
+	}
+	return parts[0], strings.ToUpper(parts[1][:1]) + parts[1][1:], nil
+}
+
+func decodeArgs(fn reflect.Value, params []json.RawMessage) ([]reflect.Value, error) {
+	t := fn.Type()
+	if t.NumIn() != len(params) {
+		return nil, fmt.Errorf("expected %d params, got %d", t.NumIn(), len(params))
+	}
+	in := make([]reflect.Value, t.NumIn())
+	for i := range params {
+		argPtr := reflect.New(t.In(i))
+		if err := json.Unmarshal(params[i], argPtr.Interface()); err != nil {
+			return nil, fmt.Errorf("param %d: %v", i, err)
+		}
+		in[i] = argPtr.Elem()
+	}
+	return in, nil
+}
+
+func unpackResult(out []reflect.Value) (interface{}, error) {
+	switch len(out) {
+	case 0:
+		return nil, nil
+	case 1:
+		if err, ok := out[0].Interface().(error); ok {
+			return nil, err
+		}
+		return out[0].Interface(), nil
+	default:
+		last := out[len(out)-1].Interface()
+		if err, ok := last.(error); ok && err != nil {
+			return nil, err
+		}
+		return out[0].Interface(), nil
+	}
+}
+
+func writeJSON(w http.ResponseWriter, resp response) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,128 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+type echoService struct{}
+
+func (echoService) Echo(msg string) (string, error) {
+	return msg, nil
+}
+
+func (echoService) Fail() error {
+	return fmt.Errorf("always fails")
+}
+
+func rawParams(t *testing.T, values ...interface{}) []json.RawMessage {
+	t.Helper()
+	params := make([]json.RawMessage, len(values))
+	for i, v := range values {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("could not marshal param %d: %v", i, err)
+		}
+		params[i] = b
+	}
+	return params
+}
+
+func TestServerCallDispatch(t *testing.T) {
+	s := NewServer()
+	if err := s.RegisterName("test", echoService{}); err != nil {
+		t.Fatalf("RegisterName() error = %v", err)
+	}
+
+	result, err := s.call("test_echo", rawParams(t, "hello"))
+	if err != nil {
+		t.Fatalf("call() error = %v", err)
+	}
+	if result != "hello" {
+		t.Fatalf("call() = %v, want %q", result, "hello")
+	}
+}
+
+func TestServerCallErrorResult(t *testing.T) {
+	s := NewServer()
+	if err := s.RegisterName("test", echoService{}); err != nil {
+		t.Fatalf("RegisterName() error = %v", err)
+	}
+
+	if _, err := s.call("test_fail", nil); err == nil {
+		t.Fatal("call() on a method returning an error should propagate it")
+	}
+}
+
+func TestServerCallUnknownNamespaceOrMethod(t *testing.T) {
+	s := NewServer()
+	if err := s.RegisterName("test", echoService{}); err != nil {
+		t.Fatalf("RegisterName() error = %v", err)
+	}
+
+	if _, err := s.call("missing_echo", nil); err == nil {
+		t.Fatal("call() with an unregistered namespace should error")
+	}
+	if _, err := s.call("test_missing", nil); err == nil {
+		t.Fatal("call() with an unknown method should error")
+	}
+	if _, err := s.call("malformed", nil); err == nil {
+		t.Fatal("call() with a malformed method name should error")
+	}
+}
+
+func TestServerRegisterNameDuplicate(t *testing.T) {
+	s := NewServer()
+	if err := s.RegisterName("test", echoService{}); err != nil {
+		t.Fatalf("RegisterName() error = %v", err)
+	}
+	if err := s.RegisterName("test", echoService{}); err == nil {
+		t.Fatal("RegisterName() with a namespace already registered should error")
+	}
+}
+
+func TestDecodeArgsParamCountMismatch(t *testing.T) {
+	s := NewServer()
+	if err := s.RegisterName("test", echoService{}); err != nil {
+		t.Fatalf("RegisterName() error = %v", err)
+	}
+
+	if _, err := s.call("test_echo", nil); err == nil {
+		t.Fatal("call() with too few params should error")
+	}
+	if _, err := s.call("test_echo", rawParams(t, "a", "b")); err == nil {
+		t.Fatal("call() with too many params should error")
+	}
+}
+
+func TestSplitMethod(t *testing.T) {
+	tests := []struct {
+		method        string
+		wantNamespace string
+		wantMethod    string
+		wantErr       bool
+	}{
+		{"shard_getCollation", "shard", "GetCollation", false},
+		{"attester_status", "attester", "Status", false},
+		{"noUnderscore", "", "", true},
+		{"empty_", "", "", true},
+	}
+
+	for _, tt := range tests {
+		namespace, method, err := splitMethod(tt.method)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("splitMethod(%q) error = nil, want error", tt.method)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("splitMethod(%q) error = %v", tt.method, err)
+			continue
+		}
+		if namespace != tt.wantNamespace || method != tt.wantMethod {
+			t.Errorf("splitMethod(%q) = (%q, %q), want (%q, %q)", tt.method, namespace, method, tt.wantNamespace, tt.wantMethod)
+		}
+	}
+}
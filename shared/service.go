@@ -0,0 +1,260 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/client/params"
+	"github.com/prysmaticlabs/prysm/shared/accounts"
+	"github.com/prysmaticlabs/prysm/shared/database"
+	"github.com/prysmaticlabs/prysm/shared/event"
+	"github.com/prysmaticlabs/prysm/shared/p2p"
+	"github.com/prysmaticlabs/prysm/shared/rpc"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logrus.WithField("prefix", "shared")
+
+// DefaultStopTimeout bounds how long StopAll waits on a single service's
+// Stop call before giving up on it and moving on to the next one.
+const DefaultStopTimeout = 5 * time.Second
+
+// Service is implemented by every long-running component a node manages.
+// Start is expected to return once the service's main loop has launched;
+// any goroutine it spawns should add itself to the ServiceContext's
+// WaitGroup and watch ctx for cancellation rather than relying on a
+// package-private closed channel.
+type Service interface {
+	Start(ctx context.Context) error
+	Stop() error
+}
+
+// ServiceConstructor builds a Service, pulling whatever dependencies it
+// needs out of the supplied ServiceContext. Constructors run in
+// registration order, so a constructor may look up any service registered
+// ahead of it.
+type ServiceConstructor func(ctx *ServiceContext) (Service, error)
+
+// ServiceContext is handed to every ServiceConstructor. It exposes the
+// node-wide singletons (database, chain config, event mux, p2p server,
+// shutdown WaitGroup, RPC server and HTTP mux) as well as typed lookup of
+// services constructed earlier in the sequence.
+type ServiceContext struct {
+	DB       *database.DB
+	Config   *params.Config
+	EventMux *event.TypeMux
+	P2P      *p2p.Server
+	Accounts *accounts.Manager
+
+	// WG is shared by every service. A service that spawns a background
+	// goroutine in Start must WG.Add(1) before launching it and WG.Done()
+	// when it returns, so Close can join every goroutine before exiting.
+	WG *sync.WaitGroup
+
+	rpcServer *rpc.Server
+	mux       *http.ServeMux
+
+	services map[reflect.Type]Service
+}
+
+// RegisterAPIs exposes each API's namespace on the node's HTTP and IPC
+// servers. Constructors call this to contribute their own RPC surface
+// (e.g. "shard_getCollation") without the node package needing to import
+// the service's package.
+func (ctx *ServiceContext) RegisterAPIs(apis []rpc.API) error {
+	for _, api := range apis {
+		if err := ctx.rpcServer.RegisterName(api.Namespace, api.Service); err != nil {
+			return fmt.Errorf("could not register %s API: %v", api.Namespace, err)
+		}
+	}
+	return nil
+}
+
+// RegisterHandler mounts h at pattern on the node's HTTP mux, so a service
+// can expose ad-hoc endpoints (metrics, health, future GraphQL) alongside
+// the JSON-RPC surface without running its own listener.
+func (ctx *ServiceContext) RegisterHandler(pattern string, h http.Handler) {
+	ctx.mux.Handle(pattern, h)
+}
+
+// Service performs a typed lookup of a previously constructed service,
+// assigning it into target. target must be a non-nil pointer to the
+// concrete type the service was constructed as, e.g. var b *beacon.Service;
+// ctx.Service(&b). Lookup is by exact type, so a pointer to an interface
+// type matches no service.
+func (ctx *ServiceContext) Service(target interface{}) error {
+	val := reflect.ValueOf(target)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() == reflect.Invalid {
+		return fmt.Errorf("target must be a non-nil pointer, got %T", target)
+	}
+	if s, ok := ctx.services[val.Elem().Type()]; ok {
+		val.Elem().Set(reflect.ValueOf(s))
+		return nil
+	}
+	return fmt.Errorf("no service of type %s registered", val.Elem().Type())
+}
+
+type registeredConstructor struct {
+	name        string
+	constructor ServiceConstructor
+}
+
+type startedService struct {
+	name    string
+	typ     reflect.Type
+	service Service
+}
+
+// ServiceRegistry tracks service constructors and, once Start is called,
+// the services they produced, in the order they were instantiated.
+type ServiceRegistry struct {
+	mu           sync.Mutex
+	ctx          *ServiceContext
+	constructors []registeredConstructor
+	started      []startedService
+
+	// StopTimeout bounds how long StopAll waits on each service's Stop
+	// call. It defaults to DefaultStopTimeout.
+	StopTimeout time.Duration
+}
+
+// Dependencies bundles the node-wide singletons a ServiceRegistry hands to
+// every constructor. Grouping them keeps NewServiceRegistry's signature
+// stable as the node grows new shared subsystems.
+type Dependencies struct {
+	DB        *database.DB
+	Config    *params.Config
+	EventMux  *event.TypeMux
+	P2P       *p2p.Server
+	Accounts  *accounts.Manager
+	WG        *sync.WaitGroup
+	RPCServer *rpc.Server
+	Mux       *http.ServeMux
+}
+
+// NewServiceRegistry creates a registry seeded with deps, which every
+// constructor's ServiceContext will expose.
+func NewServiceRegistry(deps Dependencies) *ServiceRegistry {
+	return &ServiceRegistry{
+		ctx: &ServiceContext{
+			DB:        deps.DB,
+			Config:    deps.Config,
+			EventMux:  deps.EventMux,
+			P2P:       deps.P2P,
+			Accounts:  deps.Accounts,
+			WG:        deps.WG,
+			rpcServer: deps.RPCServer,
+			mux:       deps.Mux,
+			services:  make(map[reflect.Type]Service),
+		},
+		StopTimeout: DefaultStopTimeout,
+	}
+}
+
+// Register records a constructor to be instantiated, in call order, when
+// Start is invoked. name is used only for logging.
+func (r *ServiceRegistry) Register(name string, constructor ServiceConstructor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.constructors = append(r.constructors, registeredConstructor{name: name, constructor: constructor})
+}
+
+// StartAll instantiates every registered constructor in registration
+// order and starts the resulting service, handing each constructor a
+// context populated with every service built ahead of it. If a
+// constructor or Start call fails, every service already started is
+// stopped, in reverse-dependency order, before the error is returned.
+func (r *ServiceRegistry) StartAll(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, rc := range r.constructors {
+		service, err := rc.constructor(r.ctx)
+		if err != nil {
+			r.stopStarted()
+			return fmt.Errorf("could not construct %s service: %v", rc.name, err)
+		}
+
+		t := reflect.TypeOf(service)
+		r.ctx.services[t] = service
+
+		log.Infof("Starting %s service", rc.name)
+		if err := service.Start(ctx); err != nil {
+			r.stopStarted()
+			return fmt.Errorf("could not start %s service: %v", rc.name, err)
+		}
+		r.started = append(r.started, startedService{name: rc.name, typ: t, service: service})
+	}
+	return nil
+}
+
+// StopAll stops every started service in the reverse order it was
+// started (actor -> beacon client -> rpcclient -> txpool -> p2p), so a
+// service never outlives something it depends on. Each Stop call runs
+// under StopTimeout; a service that blows its budget is logged by name
+// and StopAll moves on rather than hanging forever.
+func (r *ServiceRegistry) StopAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stopStarted()
+}
+
+// stopStarted stops services in reverse-start order. Callers must hold
+// r.mu.
+func (r *ServiceRegistry) stopStarted() {
+	timeout := r.StopTimeout
+	if timeout == 0 {
+		timeout = DefaultStopTimeout
+	}
+
+	for i := len(r.started) - 1; i >= 0; i-- {
+		s := r.started[i]
+		done := make(chan error, 1)
+		go func() { done <- s.service.Stop() }()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				log.Errorf("%s did not stop cleanly: %v", s.name, err)
+			}
+		case <-time.After(timeout):
+			log.Errorf("%s did not stop within %s, continuing shutdown", s.name, timeout)
+		}
+		delete(r.ctx.services, s.typ)
+	}
+	r.started = nil
+}
+
+// Wait blocks until every goroutine registered on the shared WaitGroup has
+// exited, or until ctx is done, whichever comes first. It returns false if
+// ctx expired first, which tells the caller to force the shutdown.
+func (r *ServiceRegistry) Wait(ctx context.Context) bool {
+	done := make(chan struct{})
+	go func() {
+		r.ctx.WG.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Service performs a typed lookup against the services this registry has
+// started, mirroring ServiceContext.Service for callers that only hold the
+// registry (e.g. the node itself, after Start has returned).
+func (r *ServiceRegistry) Service(target interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.ctx.Service(target)
+}
@@ -0,0 +1,113 @@
+package shared
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingService struct {
+	name    string
+	events  *[]string
+	mu      *sync.Mutex
+	stopErr error
+	stopFor time.Duration
+}
+
+func (s *recordingService) Start(ctx context.Context) error {
+	s.mu.Lock()
+	*s.events = append(*s.events, "start:"+s.name)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *recordingService) Stop() error {
+	if s.stopFor > 0 {
+		time.Sleep(s.stopFor)
+	}
+	s.mu.Lock()
+	*s.events = append(*s.events, "stop:"+s.name)
+	s.mu.Unlock()
+	return s.stopErr
+}
+
+func TestServiceRegistryStartStopOrder(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+
+	r := NewServiceRegistry(Dependencies{WG: new(sync.WaitGroup)})
+	r.Register("first", func(ctx *ServiceContext) (Service, error) {
+		return &recordingService{name: "first", events: &events, mu: &mu}, nil
+	})
+	r.Register("second", func(ctx *ServiceContext) (Service, error) {
+		return &recordingService{name: "second", events: &events, mu: &mu}, nil
+	})
+
+	if err := r.StartAll(context.Background()); err != nil {
+		t.Fatalf("StartAll() error = %v", err)
+	}
+	r.StopAll()
+
+	want := []string{"start:first", "start:second", "stop:second", "stop:first"}
+	if len(events) != len(want) {
+		t.Fatalf("events = %v, want %v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Fatalf("events = %v, want %v", events, want)
+		}
+	}
+}
+
+func TestServiceRegistryStopAllRespectsTimeout(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+
+	r := NewServiceRegistry(Dependencies{WG: new(sync.WaitGroup)})
+	r.StopTimeout = 10 * time.Millisecond
+	r.Register("slow", func(ctx *ServiceContext) (Service, error) {
+		return &recordingService{name: "slow", events: &events, mu: &mu, stopFor: time.Second}, nil
+	})
+
+	if err := r.StartAll(context.Background()); err != nil {
+		t.Fatalf("StartAll() error = %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r.StopAll()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("StopAll() did not return within its own StopTimeout budget")
+	}
+}
+
+type otherRecordingService struct {
+	recordingService
+}
+
+func TestServiceContextServiceLookup(t *testing.T) {
+	ctx := &ServiceContext{services: map[reflect.Type]Service{}}
+
+	svc := &recordingService{name: "x", events: &[]string{}, mu: &sync.Mutex{}}
+	ctx.services[reflect.TypeOf(svc)] = svc
+
+	var found *recordingService
+	if err := ctx.Service(&found); err != nil {
+		t.Fatalf("Service() error = %v", err)
+	}
+	if found != svc {
+		t.Fatalf("Service() assigned %v, want %v", found, svc)
+	}
+
+	var notRegistered *otherRecordingService
+	if err := ctx.Service(&notRegistered); err == nil {
+		t.Fatal("Service() for an unregistered type should error")
+	}
+}